@@ -0,0 +1,79 @@
+package gorm
+
+// CreateInBatches runs BatchCreate once with gorm:batch_size set to batchSize, so
+// batchCreateCallback inserts value in sub-batches of at most batchSize records
+// inside BatchCreate's single transaction
+func (s *DB) CreateInBatches(value interface{}, batchSize int) *DB {
+	return s.Set("gorm:batch_size", batchSize).BatchCreate(value)
+}
+
+// defaultMySQLMaxAllowedPacket 对应MySQL 8.0里max_allowed_packet的默认值(4MB)，
+// gorm:mysql_max_allowed_packet可以覆盖它
+const defaultMySQLMaxAllowedPacket = 4 << 20
+
+// estimatedBytesPerParam 给每个绑定参数一个保守的字节数估算，用来换算出每包大致能塞下多少行
+const estimatedBytesPerParam = 64
+
+// defaultMaxPlaceholderCount是没有实现maxPlaceholderCountDialect的方言的退回值，
+// 对应Postgres/SQLite绑定参数数量的上限
+const defaultMaxPlaceholderCount = 65535
+
+// maxPlaceholderCountDialect is the optional Dialect extension point for the
+// placeholder-count cap; dialects that don't implement it get defaultMaxPlaceholderCount
+type maxPlaceholderCountDialect interface {
+	MaxPlaceholderCount() int
+}
+
+func dialectMaxPlaceholderCount(scope *Scope) int {
+	if d, ok := scope.Dialect().(maxPlaceholderCountDialect); ok {
+		return d.MaxPlaceholderCount()
+	}
+	return defaultMaxPlaceholderCount
+}
+
+// resolveChunkSize picks how many rows go into a single statement: the caller-supplied
+// gorm:batch_size if one was set, capped by the dialect's placeholder limit and, for
+// MySQL, by an estimate of max_allowed_packet
+func resolveChunkSize(scope *Scope, total, paramsPerRow int) int {
+	chunkSize := total
+	if size, ok := scope.Get("gorm:batch_size"); ok {
+		if n, ok := size.(int); ok && n > 0 && n < chunkSize {
+			chunkSize = n
+		}
+	}
+
+	if paramsPerRow > 0 {
+		if max := dialectMaxPlaceholderCount(scope); chunkSize*paramsPerRow > max {
+			chunkSize = max / paramsPerRow
+		}
+
+		if scope.Dialect().GetName() == "mysql" {
+			if byPacket := mysqlMaxRowsPerPacket(scope, paramsPerRow); byPacket < chunkSize {
+				chunkSize = byPacket
+			}
+		}
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = 1
+	}
+
+	return chunkSize
+}
+
+// mysqlMaxRowsPerPacket估算单条语句能容纳多少行而不超过max_allowed_packet的字节限制
+func mysqlMaxRowsPerPacket(scope *Scope, paramsPerRow int) int {
+	maxPacket := defaultMySQLMaxAllowedPacket
+	if v, ok := scope.Get("gorm:mysql_max_allowed_packet"); ok {
+		if n, ok := v.(int); ok && n > 0 {
+			maxPacket = n
+		}
+	}
+
+	rowBytes := paramsPerRow * estimatedBytesPerParam
+	if rowBytes <= 0 {
+		return maxPacket
+	}
+
+	return maxPacket / rowBytes
+}
@@ -0,0 +1,78 @@
+package gorm
+
+import (
+	"os"
+	"testing"
+)
+
+type batchSizeUser struct {
+	Id   int64
+	Name string
+}
+
+func (u *batchSizeUser) BeforeBatchCreate(scope *Scope) error {
+	batchCreateHookCalls++
+	return nil
+}
+
+var batchCreateHookCalls int
+
+func TestCreateInBatchesSplitsIntoChunks(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := make([]*batchSizeUser, 0, 7)
+	for i := 0; i < 7; i++ {
+		users = append(users, &batchSizeUser{Name: "batch-size-user"})
+	}
+
+	batchCreateHookCalls = 0
+	result := DB.CreateInBatches(&users, 3)
+	if err := result.Error; err != nil {
+		t.Fatalf("CreateInBatches failed: %v", err)
+	}
+
+	// 7行按3个一批切分，应该产生3个子批次(3,3,1)，每个子批次触发一次BeforeBatchCreate
+	if batchCreateHookCalls != 3 {
+		t.Errorf("expected BeforeBatchCreate to run once per chunk (3), got %v", batchCreateHookCalls)
+	}
+
+	if result.RowsAffected != 7 {
+		t.Errorf("expected 7 total rows affected, got %v", result.RowsAffected)
+	}
+}
+
+func TestCreateInBatchesRollsBackOnFailingChunk(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*batchSizeUser{
+		{Id: 5001, Name: "ok-1"},
+		{Id: 5002, Name: "ok-2"},
+		{Id: 5001, Name: "duplicate-pk-triggers-failure"},
+		{Id: 5003, Name: "ok-3"},
+	}
+
+	if err := DB.CreateInBatches(&users, 1).Error; err == nil {
+		t.Fatal("expected an error from the duplicate primary key in the third chunk")
+	}
+
+	var count int
+	DB.Model(&batchSizeUser{}).Where("id IN (?)", []int64{5001, 5002, 5003}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected the whole transaction to roll back, found %v rows", count)
+	}
+}
+
+func TestResolveChunkSizeCapsByPlaceholderLimit(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	scope := DB.NewScope(&batchSizeUser{})
+	if got := resolveChunkSize(scope, 100000, 2); got*2 > dialectMaxPlaceholderCount(scope) {
+		t.Errorf("resolveChunkSize returned %v, which still overflows the placeholder limit", got)
+	}
+}
@@ -1,8 +1,10 @@
 package gorm
 
 import (
+	"database/sql"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"strings"
 )
@@ -10,29 +12,11 @@ import (
 // Define callbacks for batch creating
 func init() {
 	DefaultCallback.BatchCreate().Register("gorm:begin_transaction", beginTransactionCallback)
-	DefaultCallback.BatchCreate().Register("gorm:before_batch_create", beforeBatchCreateCallback)
 	DefaultCallback.BatchCreate().Register("gorm:update_time_stamp", updateTimeStampForBatchCreateCallback)
 	DefaultCallback.BatchCreate().Register("gorm:batch_create", batchCreateCallback)
 	DefaultCallback.BatchCreate().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
 }
 
-func beforeBatchCreateCallback(scope *Scope) {
-	if !scope.HasError() {
-		indirectScopeValue := scope.IndirectValue()
-
-		if indirectScopeValue.Kind() != reflect.Slice {
-			scope.Err(fmt.Errorf("beforeBatchCreateCallback cannot be called for non-slice value, %+v given", indirectScopeValue.Interface()))
-			return
-		}
-
-		// 只调用第一个对象的方法即可，但要注意BeforeBatchCreate方法内部要自己从scope里取出每个元素去做处理
-		// 而不是只处理当前元素，只是借用下那处的代码罢了
-		if indirectScopeValue.Len() > 0 {
-			scope.callMethod("BeforeBatchCreate", indirectScopeValue.Index(0))
-		}
-	}
-}
-
 // updateTimeStampForBatchCreateCallback will set `CreatedAt`, `UpdatedAt` when creating
 func updateTimeStampForBatchCreateCallback(scope *Scope) {
 	if !scope.HasError() {
@@ -45,16 +29,18 @@ func updateTimeStampForBatchCreateCallback(scope *Scope) {
 			return
 		}
 
+		// 如果设置了gorm:on_conflict且冲突时会更新UpdatedAt，即便调用方已经赋过值，也要刷新成当前时间
+		forceUpdatedAt := onConflictTouchesUpdatedAt(scope)
+
 		// 挨个元素去检查，为空则给予值
 		for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
 			fields := FiledsWithIndexForBatch(scope, elementIndex)
 			for _, field := range fields {
-				if !field.IsBlank {
-					continue
+				if field.Name == "CreatedAt" && field.IsBlank {
+					field.Set(now)
 				}
 
-				if field.Name == "CreatedAt" ||
-					field.Name == "UpdatedAt" {
+				if field.Name == "UpdatedAt" && (field.IsBlank || forceUpdatedAt) {
 					field.Set(now)
 				}
 			}
@@ -62,7 +48,8 @@ func updateTimeStampForBatchCreateCallback(scope *Scope) {
 	}
 }
 
-// batchCreateCallback the callback used to insert data into database
+// batchCreateCallback the callback used to insert data into database, split into
+// chunks of at most resolveChunkSize rows each
 func batchCreateCallback(scope *Scope) {
 	if !scope.HasError() {
 		defer scope.trace(NowFunc())
@@ -78,89 +65,309 @@ func batchCreateCallback(scope *Scope) {
 			return
 		}
 
-		var (
-			columns      []string
-			placeholders = make([][]string, indirectScopeValue.Len())
-		)
+		columnCount := 0
+		for _, field := range FiledsWithIndexForBatch(scope, 0) {
+			if field.IsNormal && !field.IsIgnored {
+				columnCount++
+			}
+		}
+		chunkSize := resolveChunkSize(scope, indirectScopeValue.Len(), columnCount)
+
+		var totalRowsAffected int64
+		for start := 0; start < indirectScopeValue.Len(); start += chunkSize {
+			end := start + chunkSize
+			if end > indirectScopeValue.Len() {
+				end = indirectScopeValue.Len()
+			}
 
-		// 列名获取
-		fields := FiledsWithIndexForBatch(scope, 0)
-		existColumnNames := map[string]bool{}
-		for _, field := range fields {
-			if !field.IsNormal || field.IsIgnored {
-				continue
+			// 每个子批次单独开一个Scope，这样BeforeBatchCreate等回调里读到的scope.IndirectValue()
+			// 正好是这一段子切片，而不是整个大数组；各子批次的SQLVars也互不干扰
+			chunkScope := scope.db.NewScope(indirectScopeValue.Slice(start, end).Interface())
+			chunkScope.callMethod("BeforeBatchCreate", chunkScope.IndirectValue().Index(0))
+			if chunkScope.HasError() {
+				scope.Err(chunkScope.Error)
+				return
 			}
 
-			// 因为是批量，要支持各种情况，所以这里就简单的有效列名全都给予
-			columns = append(columns, scope.Quote(field.DBName))
-			existColumnNames[field.Name] = true
+			execBatchCreate(chunkScope)
+			if chunkScope.HasError() {
+				scope.Err(chunkScope.Error)
+				return
+			}
+
+			totalRowsAffected += chunkScope.db.RowsAffected
 		}
 
-		if len(columns) <= 0 {
-			scope.Err(fmt.Errorf("batchCreateCallback cannot be called for empty columns, %+v given", indirectScopeValue.Interface()))
-			return
+		scope.db.RowsAffected = totalRowsAffected
+	}
+}
+
+// execBatchCreate builds and executes one INSERT statement for every element held by scope
+func execBatchCreate(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	indirectScopeValue := scope.IndirectValue()
+
+	var (
+		columns       []string
+		placeholders  = make([][]string, indirectScopeValue.Len())
+		primaryFields = make([]*Field, indirectScopeValue.Len())
+	)
+
+	// 列名获取
+	fields := FiledsWithIndexForBatch(scope, 0)
+	existColumnNames := map[string]bool{}
+	for _, field := range fields {
+		if !field.IsNormal || field.IsIgnored {
+			continue
 		}
 
-		// 塞入内容，因为是数组，所以需要挨个去塞
-		for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
-			valuePlaceholders := []string{}
+		// 因为是批量，要支持各种情况，所以这里就简单的有效列名全都给予
+		columns = append(columns, scope.Quote(field.DBName))
+		existColumnNames[field.Name] = true
+	}
 
-			fields := FiledsWithIndexForBatch(scope, elementIndex)
-			for _, field := range fields {
-				if existColumnNames[field.Name] {
-					var v interface{}
-					if !field.IsBlank {
-						v = field.Field.Interface()
-					} else {
-						// 如果不是主键
-						if !field.IsPrimaryKey {
-							// 若有默认值，就直接塞入默认值即可
-							if field.HasDefaultValue {
-								v = field.TagSettings["DEFAULT"]
-								field.Set(v) // 回写原对象
-							} else {
-								// 没默认值的话，就用原对象值，0啊空字符串什么的
-								v = field.Field.Interface()
-							}
+	if len(columns) <= 0 {
+		scope.Err(fmt.Errorf("batchCreateCallback cannot be called for empty columns, %+v given", indirectScopeValue.Interface()))
+		return
+	}
+
+	// 塞入内容，因为是数组，所以需要挨个去塞
+	for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
+		valuePlaceholders := []string{}
+
+		fields := FiledsWithIndexForBatch(scope, elementIndex)
+		for _, field := range fields {
+			if field.IsPrimaryKey && field.IsBlank {
+				// 记下这一行还没有主键值，等insert执行完后要把生成的自增ID回写进去
+				primaryFields[elementIndex] = field
+			}
+
+			if existColumnNames[field.Name] {
+				var v interface{}
+				if !field.IsBlank {
+					v = field.Field.Interface()
+				} else {
+					// 如果不是主键
+					if !field.IsPrimaryKey {
+						// 若有默认值，就直接塞入默认值即可
+						if field.HasDefaultValue {
+							v = field.TagSettings["DEFAULT"]
+							field.Set(v) // 回写原对象
+						} else {
+							// 没默认值的话，就用原对象值，0啊空字符串什么的
+							v = field.Field.Interface()
 						}
-						// 否则的话v就是nil嘛，然后最终会体现成NULL，能自动支持主键的自增行为
 					}
-
-					valuePlaceholders = append(valuePlaceholders, scope.AddToVars(v))
+					// 否则的话v就是nil嘛，然后最终会体现成NULL，能自动支持主键的自增行为
 				}
+
+				valuePlaceholders = append(valuePlaceholders, scope.AddToVars(v))
 			}
+		}
+
+		placeholders[elementIndex] = valuePlaceholders
+	}
+
+	// 构造Values语句
+	valuePlaceholders := []string{}
+	for _, placeholder := range placeholders {
+		valuePlaceholders = append(valuePlaceholders, fmt.Sprintf("(%s)", strings.Join(placeholder, ",")))
+	}
+
+	// 额外Option
+	var extraOption string
+	if str, ok := scope.Get("gorm:insert_option"); ok {
+		extraOption = fmt.Sprint(str)
+	}
 
-			placeholders[elementIndex] = valuePlaceholders
+	skipReturning := false
+	if skip, ok := scope.Get("gorm:batch_insert_skip_returning"); ok {
+		if skipBool, ok := skip.(bool); ok {
+			skipReturning = skipBool
 		}
+	}
 
-		// 构造Values语句
-		valuePlaceholders := []string{}
-		for _, placeholder := range placeholders {
-			valuePlaceholders = append(valuePlaceholders, fmt.Sprintf("(%s)", strings.Join(placeholder, ",")))
+	var onConflictClause string
+	if conflict := getOnConflict(scope); conflict != nil {
+		clause, err := buildOnConflictClause(scope, conflict, columns)
+		if scope.Err(err) != nil {
+			return
 		}
+		onConflictClause = clause
+	}
+
+	primaryField := scope.PrimaryField()
 
-		// 额外Option
-		var extraOption string
-		if str, ok := scope.Get("gorm:insert_option"); ok {
-			extraOption = fmt.Sprint(str)
+	// Postgres可以直接在INSERT语句后面加RETURNING，按插入顺序把自增主键整批拿回来；
+	// 但DO NOTHING会让冲突的那几行完全不出现在RETURNING结果里，插入顺序和返回行就对不上了，
+	// 这种情况单独走execBatchCreatePostgresDoNothing，不能假设一行插入对应一行RETURNING
+	if !skipReturning && primaryField != nil && scope.Dialect().GetName() == "postgres" {
+		if conflict := getOnConflict(scope); conflict != nil && conflict.DoNothing {
+			execBatchCreatePostgresDoNothing(scope, columns, valuePlaceholders, extraOption, onConflictClause, primaryField, conflict, primaryFields)
+			return
 		}
 
-		// 构造prepare语句
 		scope.Raw(fmt.Sprintf(
-			"INSERT INTO %v (%v) VALUES %v%v",
+			"INSERT INTO %v (%v) VALUES %v%v%v RETURNING %v",
 			scope.QuotedTableName(),
 			strings.Join(columns, ","),
 			strings.Join(valuePlaceholders, ","),
 			addExtraSpaceIfExist(extraOption),
+			addExtraSpaceIfExist(onConflictClause),
+			scope.Quote(primaryField.DBName),
+		))
+
+		if rows, err := scope.SQLDB().Query(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+			defer rows.Close()
+
+			// RETURNING按插入顺序每行返回一行，跟primaryFields按位置一一对应；
+			// 只有该位置本来就是空主键的才需要回写，已经有主键的那几行直接跳过不Set
+			for elementIndex := 0; rows.Next(); elementIndex++ {
+				var id int64
+				if scope.Err(rows.Scan(&id)) != nil {
+					break
+				}
+				if elementIndex < len(primaryFields) && primaryFields[elementIndex] != nil {
+					primaryFields[elementIndex].Set(id)
+				}
+			}
+			scope.db.RowsAffected = int64(indirectScopeValue.Len())
+		}
+		return
+	}
+
+	// 构造prepare语句
+	scope.Raw(fmt.Sprintf(
+		"INSERT INTO %v (%v) VALUES %v%v%v",
+		scope.QuotedTableName(),
+		strings.Join(columns, ","),
+		strings.Join(valuePlaceholders, ","),
+		addExtraSpaceIfExist(extraOption),
+		addExtraSpaceIfExist(onConflictClause),
+	))
+
+	// 执行语句
+	if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+		scope.db.RowsAffected, _ = result.RowsAffected()
+
+		// MySQL的ON DUPLICATE KEY UPDATE分支里，被更新的那几行根本不会分配新的自增ID，
+		// LastInsertId()+步长这个线性回填假设不再成立，所以一旦带了on_conflict就整个跳过，
+		// 不去猜哪些行是真正新插入的
+		onConflictOnMySQL := scope.Dialect().GetName() == "mysql" && getOnConflict(scope) != nil
+		if !skipReturning && primaryField != nil && !onConflictOnMySQL {
+			assignBatchInsertIDs(scope, result, primaryFields)
+		}
+	}
+}
+
+// execBatchCreatePostgresDoNothing runs the INSERT without RETURNING (DO NOTHING means
+// some rows won't come back, so RETURNING can't be zipped against primaryFields by
+// position) and backfills blank primary keys with a follow-up lookup on the ON CONFLICT
+// target columns instead
+func execBatchCreatePostgresDoNothing(scope *Scope, columns, valuePlaceholders []string, extraOption, onConflictClause string, primaryField *Field, conflict *OnConflict, primaryFields []*Field) {
+	scope.Raw(fmt.Sprintf(
+		"INSERT INTO %v (%v) VALUES %v%v%v",
+		scope.QuotedTableName(),
+		strings.Join(columns, ","),
+		strings.Join(valuePlaceholders, ","),
+		addExtraSpaceIfExist(extraOption),
+		addExtraSpaceIfExist(onConflictClause),
+	))
+
+	result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...)
+	if scope.Err(err) != nil {
+		return
+	}
+	scope.db.RowsAffected, _ = result.RowsAffected()
+
+	for elementIndex, field := range primaryFields {
+		if field == nil {
+			continue
+		}
+
+		elementFields := FiledsWithIndexForBatch(scope, elementIndex)
+		lookupScope := scope.db.NewScope(scope.Value)
+
+		var whereClauses []string
+		for _, column := range conflict.Columns {
+			for _, f := range elementFields {
+				if f.DBName == column {
+					whereClauses = append(whereClauses, fmt.Sprintf("%v = %v", scope.Quote(column), lookupScope.AddToVars(f.Field.Interface())))
+					break
+				}
+			}
+		}
+		if len(whereClauses) != len(conflict.Columns) {
+			continue
+		}
+
+		lookupScope.Raw(fmt.Sprintf(
+			"SELECT %v FROM %v WHERE %v",
+			scope.Quote(primaryField.DBName),
+			scope.QuotedTableName(),
+			strings.Join(whereClauses, " AND "),
 		))
 
-		// 执行语句
-		if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
-			scope.db.RowsAffected, _ = result.RowsAffected()
+		var id int64
+		if lookupScope.SQLDB().QueryRow(lookupScope.SQL, lookupScope.SQLVars...).Scan(&id) == nil {
+			field.Set(id)
+		}
+	}
+}
 
-			// TODO: 因为mysql底层driver不支持批量插入拿取最终insert id 所以这里也就没办法回写了，暂时没招
+// assignBatchInsertIDs backfills blank primary keys from LastInsertId() plus the
+// per-row stride (MySQL's auto_increment_increment, 1 for SQLite)
+func assignBatchInsertIDs(scope *Scope, result sql.Result, primaryFields []*Field) {
+	firstID, err := result.LastInsertId()
+	if err != nil || firstID == 0 {
+		return
+	}
+
+	step := int64(1)
+	if scope.Dialect().GetName() == "mysql" {
+		step = mysqlAutoIncrementIncrement(scope)
+	}
+
+	blankCount := int64(0)
+	for _, field := range primaryFields {
+		if field == nil {
+			continue
 		}
+		field.Set(firstID + blankCount*step)
+		blankCount++
+	}
+}
+
+var mysqlAutoIncrementIncrementCache = struct {
+	sync.RWMutex
+	m map[SQLCommon]int64
+}{m: map[SQLCommon]int64{}}
+
+// mysqlAutoIncrementIncrement queries and caches auto_increment_increment per connection
+func mysqlAutoIncrementIncrement(scope *Scope) int64 {
+	db := scope.SQLDB()
+
+	mysqlAutoIncrementIncrementCache.RLock()
+	step, ok := mysqlAutoIncrementIncrementCache.m[db]
+	mysqlAutoIncrementIncrementCache.RUnlock()
+	if ok {
+		return step
+	}
+
+	step = 1
+	var variableName string
+	if row := db.QueryRow("SHOW VARIABLES LIKE 'auto_increment_increment'"); row != nil {
+		row.Scan(&variableName, &step)
 	}
+
+	mysqlAutoIncrementIncrementCache.Lock()
+	mysqlAutoIncrementIncrementCache.m[db] = step
+	mysqlAutoIncrementIncrementCache.Unlock()
+
+	return step
 }
 
 func FiledsWithIndexForBatch(scope *Scope, index int) []*Field {
@@ -0,0 +1,221 @@
+package gorm
+
+import "reflect"
+
+// Hook association handling into the BatchCreate chain: belongs_to parents must land
+// (and back-fill their FK onto the child slice) before the parent INSERT runs, while
+// has_one/has_many children can only be inserted once the parent rows exist and their
+// generated primary keys have been written back
+func init() {
+	DefaultCallback.BatchCreate().Before("gorm:batch_create").Register("gorm:batch_save_before_associations", batchSaveBeforeAssociationsCallback)
+	DefaultCallback.BatchCreate().Before("gorm:commit_or_rollback_transaction").Register("gorm:batch_save_after_associations", batchSaveAfterAssociationsCallback)
+}
+
+// batchAssociationsSkipped mirrors the single-row Create path's escape hatch
+func batchAssociationsSkipped(scope *Scope) bool {
+	if skip, ok := scope.Get("gorm:batch_save_associations"); ok {
+		if skipBool, ok := skip.(bool); ok {
+			return !skipBool
+		}
+	}
+	return false
+}
+
+// addressableAssociation returns a pointer to an association field that the caller
+// actually populated: nil pointer fields and untouched (zero-value) struct fields are
+// reported as not-present. Value-typed fields are always returned via Addr() so a
+// generated primary key can be written back onto the parent struct in place
+func addressableAssociation(associationField reflect.Value) (reflect.Value, bool) {
+	if associationField.Kind() == reflect.Ptr {
+		if associationField.IsNil() {
+			return reflect.Value{}, false
+		}
+		return associationField, true
+	}
+
+	if associationField.Kind() != reflect.Struct || !associationField.CanAddr() {
+		return reflect.Value{}, false
+	}
+
+	if isBlank(associationField) {
+		return reflect.Value{}, false
+	}
+
+	return associationField.Addr(), true
+}
+
+// associationBatchCreateDB strips the options that only make sense against the
+// top-level BatchCreate call (on_conflict's column/update list is specific to the
+// parent table, batch_size and batch_insert_skip_returning are the caller's tuning for
+// that table) before recursing into an association's own BatchCreate, so they don't
+// silently apply to the child's table too
+func associationBatchCreateDB(db *DB) *DB {
+	return db.Set("gorm:on_conflict", (*OnConflict)(nil)).
+		Set("gorm:batch_size", 0).
+		Set("gorm:batch_insert_skip_returning", false)
+}
+
+// batchSaveBeforeAssociationsCallback resolves belongs_to associations before the
+// parent rows are inserted: an association that's present but still has a blank
+// primary key is batch-inserted first, then its key is back-filled onto the parent's
+// foreign key column; an association whose primary key is already set is assumed to
+// already exist and is only used for the FK copy
+func batchSaveBeforeAssociationsCallback(scope *Scope) {
+	if scope.HasError() || batchAssociationsSkipped(scope) {
+		return
+	}
+
+	indirectScopeValue := scope.IndirectValue()
+	if indirectScopeValue.Kind() != reflect.Slice || indirectScopeValue.Len() <= 0 {
+		return
+	}
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.Relationship == nil || field.Relationship.Kind != "belongs_to" {
+			continue
+		}
+
+		relationship := field.Relationship
+		var unsaved reflect.Value
+
+		for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
+			parent := reflect.Indirect(indirectScopeValue.Index(elementIndex))
+
+			childPtr, ok := addressableAssociation(parent.FieldByName(field.Name))
+			if !ok {
+				continue
+			}
+
+			associationPK := scope.db.NewScope(childPtr.Interface()).PrimaryField()
+			if associationPK == nil || !associationPK.IsBlank {
+				continue
+			}
+
+			if !unsaved.IsValid() {
+				unsaved = reflect.MakeSlice(reflect.SliceOf(childPtr.Type()), 0, indirectScopeValue.Len())
+			}
+			unsaved = reflect.Append(unsaved, childPtr)
+		}
+
+		if unsaved.IsValid() && unsaved.Len() > 0 {
+			associationScope := associationBatchCreateDB(scope.db).NewScope(unsaved.Interface())
+			associationScope.callCallbacks(scope.db.parent.callbacks.batchCreates)
+			if associationScope.HasError() {
+				scope.Err(associationScope.Error)
+				return
+			}
+		}
+
+		for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
+			parent := reflect.Indirect(indirectScopeValue.Index(elementIndex))
+
+			childPtr, ok := addressableAssociation(parent.FieldByName(field.Name))
+			if !ok {
+				continue
+			}
+			association := reflect.Indirect(childPtr)
+
+			for i, foreignFieldName := range relationship.ForeignFieldNames {
+				associationFieldValue := association.FieldByName(relationship.AssociationForeignFieldNames[i])
+				parent.FieldByName(foreignFieldName).Set(associationFieldValue)
+			}
+		}
+	}
+}
+
+// batchSaveAfterAssociationsCallback inserts has_one/has_many children once the parent
+// rows (and their primary keys) exist
+func batchSaveAfterAssociationsCallback(scope *Scope) {
+	if scope.HasError() || batchAssociationsSkipped(scope) {
+		return
+	}
+
+	indirectScopeValue := scope.IndirectValue()
+	if indirectScopeValue.Kind() != reflect.Slice || indirectScopeValue.Len() <= 0 {
+		return
+	}
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.Relationship == nil {
+			continue
+		}
+
+		switch field.Relationship.Kind {
+		case "has_one", "has_many":
+			batchCreateHasManyAssociations(scope, field)
+			if scope.HasError() {
+				return
+			}
+		}
+	}
+}
+
+// batchCreateHasManyAssociations collects field's children across every element of
+// scope's slice, back-fills the parent's foreign key onto each of them, and
+// batch-inserts them together as a single child slice
+func batchCreateHasManyAssociations(scope *Scope, field *StructField) {
+	relationship := field.Relationship
+	indirectScopeValue := scope.IndirectValue()
+
+	var (
+		children     reflect.Value
+		childAddrs   []reflect.Value
+		childElemPtr reflect.Type
+	)
+
+	for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
+		parent := reflect.Indirect(indirectScopeValue.Index(elementIndex))
+		childValue := parent.FieldByName(field.Name)
+
+		var childElems []reflect.Value
+		switch childValue.Kind() {
+		case reflect.Slice:
+			for childIndex := 0; childIndex < childValue.Len(); childIndex++ {
+				childElems = append(childElems, childValue.Index(childIndex))
+			}
+		case reflect.Ptr:
+			if !childValue.IsNil() {
+				childElems = append(childElems, childValue)
+			}
+		case reflect.Struct:
+			// has_one declared as a plain struct (not a pointer) still needs handling,
+			// but only when the caller actually populated it - same blank check as the
+			// belongs_to path above, reused here via addressableAssociation
+			if childPtr, ok := addressableAssociation(childValue); ok {
+				childElems = append(childElems, childPtr)
+			}
+		}
+
+		for _, child := range childElems {
+			childStruct := reflect.Indirect(child)
+
+			for i, foreignFieldName := range relationship.ForeignFieldNames {
+				associationFieldValue := parent.FieldByName(relationship.AssociationForeignFieldNames[i])
+				childStruct.FieldByName(foreignFieldName).Set(associationFieldValue)
+			}
+
+			if child.Kind() != reflect.Ptr {
+				child = childStruct.Addr()
+			}
+			if childElemPtr == nil {
+				childElemPtr = child.Type()
+			}
+			childAddrs = append(childAddrs, child)
+		}
+	}
+
+	if len(childAddrs) == 0 {
+		return
+	}
+
+	children = reflect.MakeSlice(reflect.SliceOf(childElemPtr), 0, len(childAddrs))
+	for _, child := range childAddrs {
+		children = reflect.Append(children, child)
+	}
+
+	childScope := associationBatchCreateDB(scope.db).NewScope(children.Interface())
+	childScope.callCallbacks(scope.db.parent.callbacks.batchCreates)
+	if childScope.HasError() {
+		scope.Err(childScope.Error)
+	}
+}
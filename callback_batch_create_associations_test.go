@@ -0,0 +1,184 @@
+package gorm
+
+import (
+	"os"
+	"testing"
+)
+
+type assocCompany struct {
+	Id   int64
+	Name string
+}
+
+type assocAddress struct {
+	Id   int64
+	City string
+}
+
+type assocProfile struct {
+	Id     int64
+	UserId int64
+	Bio    string
+}
+
+type assocUser struct {
+	Id        int64
+	Name      string
+	CompanyId int64
+	Company   *assocCompany
+	AddressId int64
+	Address   assocAddress
+	Profile   assocProfile
+	Pets      []*assocPet
+}
+
+type assocPet struct {
+	Id     int64
+	UserId int64
+	Name   string
+}
+
+func TestBatchCreateSkipsNilBelongsTo(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*assocUser{{Name: "no-company"}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	if users[0].CompanyId != 0 {
+		t.Errorf("expected CompanyId to stay 0 for a nil Company, got %v", users[0].CompanyId)
+	}
+}
+
+func TestBatchCreateReusesExistingBelongsTo(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	existing := []*assocCompany{{Name: "already-saved"}}
+	if err := DB.BatchCreate(&existing).Error; err != nil {
+		t.Fatalf("seed BatchCreate failed: %v", err)
+	}
+
+	users := []*assocUser{{Name: "u1", Company: existing[0]}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	if users[0].CompanyId != existing[0].Id {
+		t.Errorf("expected CompanyId %v to be copied from the existing Company, got %v", existing[0].Id, users[0].CompanyId)
+	}
+
+	var count int
+	DB.Model(&assocCompany{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected the already-saved Company not to be re-inserted, found %v rows", count)
+	}
+}
+
+func TestBatchCreateInsertsNewBelongsTo(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*assocUser{
+		{Name: "u1", Company: &assocCompany{Name: "brand-new-1"}},
+		{Name: "u2", Company: &assocCompany{Name: "brand-new-2"}},
+	}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	for _, user := range users {
+		if user.CompanyId == 0 || user.CompanyId != user.Company.Id {
+			t.Errorf("expected CompanyId to be backfilled from the newly created Company, got %v vs %v", user.CompanyId, user.Company.Id)
+		}
+	}
+}
+
+func TestBatchCreateSkipsZeroValueBelongsTo(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*assocUser{{Name: "untouched-address-owner"}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	if users[0].AddressId != 0 {
+		t.Errorf("expected AddressId to stay 0 for an untouched value-typed Address, got %v", users[0].AddressId)
+	}
+
+	var count int
+	DB.Model(&assocAddress{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected an untouched value-typed Address not to be force-inserted, found %v rows", count)
+	}
+}
+
+func TestBatchCreateSkipsZeroValueHasOne(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*assocUser{{Name: "untouched-profile-owner"}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	var count int
+	DB.Model(&assocProfile{}).Where("user_id = ?", users[0].Id).Count(&count)
+	if count != 0 {
+		t.Errorf("expected an untouched value-typed Profile not to be force-inserted, found %v rows", count)
+	}
+}
+
+func TestBatchCreateAssociationsDontInheritOnConflict(t *testing.T) {
+	dialect := os.Getenv("GORM_DIALECT")
+	if dialect != "mysql" && dialect != "postgres" {
+		t.Skip("GORM_DIALECT is not mysql/postgres, skip")
+	}
+
+	// address_id只存在于assocUser上，assocCompany没有这一列；如果父级BatchCreate的
+	// gorm:on_conflict泄漏到Company的子插入里，这条语句会因为引用了不存在的列而直接报错
+	conflict := &OnConflict{Columns: []string{"id"}, DoUpdates: []string{"address_id"}}
+	users := []*assocUser{{Name: "u1", Company: &assocCompany{Name: "leak-check"}}}
+	if err := DB.Set("gorm:on_conflict", conflict).BatchCreate(&users).Error; err != nil {
+		t.Fatalf("expected the parent's on_conflict option not to leak into the Company insert, got: %v", err)
+	}
+
+	if users[0].CompanyId == 0 {
+		t.Errorf("expected CompanyId to be backfilled despite the parent's on_conflict option")
+	}
+}
+
+func TestBatchCreateHasOneValueAndHasMany(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*assocUser{{
+		Name:    "with-children",
+		Profile: assocProfile{Bio: "hello"},
+		Pets:    []*assocPet{{Name: "rex"}, {Name: "fido"}},
+	}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	var profile assocProfile
+	DB.Where("user_id = ?", users[0].Id).First(&profile)
+	if profile.Bio != "hello" {
+		t.Errorf("expected the value-typed Profile to be inserted with the parent's FK, got %+v", profile)
+	}
+
+	var petCount int
+	DB.Model(&assocPet{}).Where("user_id = ?", users[0].Id).Count(&petCount)
+	if petCount != 2 {
+		t.Errorf("expected both Pets to be inserted with the parent's FK, found %v", petCount)
+	}
+}
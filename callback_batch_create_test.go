@@ -0,0 +1,94 @@
+package gorm
+
+import (
+	"os"
+	"testing"
+)
+
+type batchCreateIDUser struct {
+	Id   int64
+	Name string
+}
+
+func TestBatchCreateBackfillsIDs(t *testing.T) {
+	tests := []struct {
+		dialect string
+		users   []*batchCreateIDUser
+	}{
+		{"mysql", []*batchCreateIDUser{{Name: "mysql-1"}, {Name: "mysql-2"}, {Name: "mysql-3"}}},
+		{"postgres", []*batchCreateIDUser{{Name: "postgres-1"}, {Name: "postgres-2"}, {Name: "postgres-3"}}},
+		{"sqlite3", []*batchCreateIDUser{{Name: "sqlite-1"}, {Name: "sqlite-2"}, {Name: "sqlite-3"}}},
+	}
+
+	for _, test := range tests {
+		test := test
+		// 每个方言一个子测试，这样t.Skipf只跳过不匹配的那一行，不会把整个测试提前结束
+		t.Run(test.dialect, func(t *testing.T) {
+			if os.Getenv("GORM_DIALECT") != test.dialect {
+				t.Skipf("GORM_DIALECT is not %v, skip", test.dialect)
+			}
+
+			if err := DB.BatchCreate(&test.users).Error; err != nil {
+				t.Fatalf("%v: BatchCreate failed: %v", test.dialect, err)
+			}
+
+			seen := map[int64]bool{}
+			for _, user := range test.users {
+				if user.Id == 0 {
+					t.Errorf("%v: expected generated id, got 0", test.dialect)
+				}
+				if seen[user.Id] {
+					t.Errorf("%v: id %v assigned to more than one row", test.dialect, user.Id)
+				}
+				seen[user.Id] = true
+			}
+		})
+	}
+}
+
+func TestBatchCreateKeepsExplicitPrimaryKeys(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*batchCreateIDUser{
+		{Id: 1001, Name: "explicit-1"},
+		{Name: "blank-1"},
+		{Id: 1002, Name: "explicit-2"},
+		{Name: "blank-2"},
+	}
+
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	if users[0].Id != 1001 {
+		t.Errorf("expected explicit id 1001 to survive, got %v", users[0].Id)
+	}
+	if users[2].Id != 1002 {
+		t.Errorf("expected explicit id 1002 to survive, got %v", users[2].Id)
+	}
+	if users[1].Id == 0 || users[1].Id == users[0].Id || users[1].Id == users[2].Id {
+		t.Errorf("expected blank-1 to get its own generated id, got %v", users[1].Id)
+	}
+	if users[3].Id == 0 || users[3].Id == users[1].Id {
+		t.Errorf("expected blank-2 to get its own generated id, got %v", users[3].Id)
+	}
+}
+
+func TestBatchCreateSkipReturning(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*batchCreateIDUser{{Name: "skip-1"}, {Name: "skip-2"}}
+	if err := DB.Set("gorm:batch_insert_skip_returning", true).BatchCreate(&users).Error; err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	for _, user := range users {
+		if user.Id != 0 {
+			t.Errorf("expected id to stay blank with gorm:batch_insert_skip_returning, got %v", user.Id)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Define callbacks for batch deleting
+func init() {
+	DefaultCallback.BatchDelete().Register("gorm:begin_transaction", beginTransactionCallback)
+	DefaultCallback.BatchDelete().Register("gorm:before_batch_delete", beforeBatchDeleteCallback)
+	DefaultCallback.BatchDelete().Register("gorm:batch_delete", batchDeleteCallback)
+	DefaultCallback.BatchDelete().Register("gorm:after_batch_delete", afterBatchDeleteCallback)
+	DefaultCallback.BatchDelete().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
+}
+
+// BatchDelete deletes every element of value (a slice keyed by primary key) with one
+// `DELETE FROM tbl WHERE id IN (...)` statement per chunk, mirroring BatchCreate
+func (s *DB) BatchDelete(value interface{}) *DB {
+	scope := s.NewScope(value)
+	return scope.callCallbacks(s.parent.callbacks.batchDeletes).db
+}
+
+func beforeBatchDeleteCallback(scope *Scope) {
+	if !scope.HasError() {
+		indirectScopeValue := scope.IndirectValue()
+
+		if indirectScopeValue.Kind() != reflect.Slice {
+			scope.Err(fmt.Errorf("beforeBatchDeleteCallback cannot be called for non-slice value, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		if indirectScopeValue.Len() > 0 {
+			scope.callMethod("BeforeBatchDelete", indirectScopeValue.Index(0))
+		}
+	}
+}
+
+func afterBatchDeleteCallback(scope *Scope) {
+	if !scope.HasError() {
+		indirectScopeValue := scope.IndirectValue()
+
+		if indirectScopeValue.Kind() == reflect.Slice && indirectScopeValue.Len() > 0 {
+			scope.callMethod("AfterBatchDelete", indirectScopeValue.Index(0))
+		}
+	}
+}
+
+// batchDeleteCallback builds one `DELETE FROM tbl WHERE id IN (...)` statement per
+// chunk, so a slice of structs keyed by primary key can be removed without one
+// round-trip per row
+func batchDeleteCallback(scope *Scope) {
+	if !scope.HasError() {
+		defer scope.trace(NowFunc())
+
+		indirectScopeValue := scope.IndirectValue()
+		if indirectScopeValue.Kind() != reflect.Slice {
+			scope.Err(fmt.Errorf("batchDeleteCallback cannot be called for non-slice value, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		if indirectScopeValue.Len() <= 0 {
+			scope.Err(fmt.Errorf("batchDeleteCallback cannot be called for empty slice, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		primaryField := scope.PrimaryField()
+		if primaryField == nil {
+			scope.Err(fmt.Errorf("batchDeleteCallback requires a primary key, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		chunkSize := resolveChunkSize(scope, indirectScopeValue.Len(), 1)
+
+		var totalRowsAffected int64
+		for start := 0; start < indirectScopeValue.Len(); start += chunkSize {
+			end := start + chunkSize
+			if end > indirectScopeValue.Len() {
+				end = indirectScopeValue.Len()
+			}
+
+			chunkScope := scope.db.NewScope(indirectScopeValue.Slice(start, end).Interface())
+			rowsAffected, err := execBatchDelete(chunkScope, primaryField)
+			if scope.Err(err) != nil {
+				return
+			}
+			totalRowsAffected += rowsAffected
+		}
+
+		scope.db.RowsAffected = totalRowsAffected
+	}
+}
+
+// execBatchDelete runs a single `DELETE ... WHERE id IN (...)` for every element held
+// by scope
+func execBatchDelete(scope *Scope, primaryField *Field) (int64, error) {
+	indirectScopeValue := scope.IndirectValue()
+
+	ids := make([]string, 0, indirectScopeValue.Len())
+	for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
+		for _, field := range FiledsWithIndexForBatch(scope, elementIndex) {
+			if field.IsPrimaryKey {
+				ids = append(ids, scope.AddToVars(field.Field.Interface()))
+			}
+		}
+	}
+
+	scope.Raw(fmt.Sprintf(
+		"DELETE FROM %v WHERE %v IN (%v)",
+		scope.QuotedTableName(),
+		scope.Quote(primaryField.DBName),
+		strings.Join(ids, ","),
+	))
+
+	result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
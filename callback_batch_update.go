@@ -0,0 +1,266 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Define callbacks for batch updating
+func init() {
+	DefaultCallback.BatchUpdate().Register("gorm:begin_transaction", beginTransactionCallback)
+	DefaultCallback.BatchUpdate().Register("gorm:before_batch_update", beforeBatchUpdateCallback)
+	DefaultCallback.BatchUpdate().Register("gorm:batch_update", batchUpdateCallback)
+	DefaultCallback.BatchUpdate().Register("gorm:after_batch_update", afterBatchUpdateCallback)
+	DefaultCallback.BatchUpdate().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
+}
+
+// BatchUpdate updates every element of value (a slice keyed by primary key), mirroring
+// BatchCreate: with the default "case" strategy one `UPDATE ... SET col = CASE id WHEN
+// ... END` statement runs per chunk; a dialect whose BatchUpdateStrategy is "bulk" gets
+// the whole slice through a temporary table instead, without chunking
+func (s *DB) BatchUpdate(value interface{}) *DB {
+	scope := s.NewScope(value)
+	return scope.callCallbacks(s.parent.callbacks.batchUpdates).db
+}
+
+func beforeBatchUpdateCallback(scope *Scope) {
+	if !scope.HasError() {
+		indirectScopeValue := scope.IndirectValue()
+
+		if indirectScopeValue.Kind() != reflect.Slice {
+			scope.Err(fmt.Errorf("beforeBatchUpdateCallback cannot be called for non-slice value, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		if indirectScopeValue.Len() > 0 {
+			scope.callMethod("BeforeBatchUpdate", indirectScopeValue.Index(0))
+		}
+	}
+}
+
+func afterBatchUpdateCallback(scope *Scope) {
+	if !scope.HasError() {
+		indirectScopeValue := scope.IndirectValue()
+
+		if indirectScopeValue.Kind() == reflect.Slice && indirectScopeValue.Len() > 0 {
+			scope.callMethod("AfterBatchUpdate", indirectScopeValue.Index(0))
+		}
+	}
+}
+
+// batchUpdateStrategyDialect is the optional Dialect extension point for BatchUpdate's
+// SQL shape; dialects that don't implement it get the "case" strategy below
+type batchUpdateStrategyDialect interface {
+	BatchUpdateStrategy() string
+}
+
+// dialectBatchUpdateStrategy returns "case" for any dialect that doesn't explicitly opt
+// into something else - every built-in dialect (mysql/postgres/sqlite3) can do an
+// efficient CASE id WHEN ... END, so "case" is the default rather than an error
+func dialectBatchUpdateStrategy(scope *Scope) string {
+	if d, ok := scope.Dialect().(batchUpdateStrategyDialect); ok {
+		return d.BatchUpdateStrategy()
+	}
+	return "case"
+}
+
+// batchUpdateCallback builds one UPDATE statement per chunk, so a slice of structs
+// keyed by primary key can be persisted without one round-trip per row. The SQL shape
+// comes from dialectBatchUpdateStrategy: "case" drives a CASE id WHEN ... END statement,
+// "bulk" goes through execBatchUpdateViaTempTable for dialects that can't do CASE
+// efficiently
+func batchUpdateCallback(scope *Scope) {
+	if !scope.HasError() {
+		defer scope.trace(NowFunc())
+
+		indirectScopeValue := scope.IndirectValue()
+		if indirectScopeValue.Kind() != reflect.Slice {
+			scope.Err(fmt.Errorf("batchUpdateCallback cannot be called for non-slice value, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		if indirectScopeValue.Len() <= 0 {
+			scope.Err(fmt.Errorf("batchUpdateCallback cannot be called for empty slice, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		primaryField := scope.PrimaryField()
+		if primaryField == nil {
+			scope.Err(fmt.Errorf("batchUpdateCallback requires a primary key, %+v given", indirectScopeValue.Interface()))
+			return
+		}
+
+		switch strategy := dialectBatchUpdateStrategy(scope); strategy {
+		case "case":
+			batchUpdateViaCase(scope, primaryField)
+		case "bulk":
+			rowsAffected, err := execBatchUpdateViaTempTable(scope, primaryField)
+			if scope.Err(err) == nil {
+				scope.db.RowsAffected = rowsAffected
+			}
+		default:
+			scope.Err(fmt.Errorf("gorm: unsupported BatchUpdateStrategy %q from dialect %v", strategy, scope.Dialect().GetName()))
+		}
+	}
+}
+
+// batchUpdateViaCase chunks the slice and runs execBatchUpdate (CASE id WHEN ... END) on
+// each chunk
+func batchUpdateViaCase(scope *Scope, primaryField *Field) {
+	indirectScopeValue := scope.IndirectValue()
+
+	columnCount := 0
+	for _, field := range FiledsWithIndexForBatch(scope, 0) {
+		if field.IsNormal && !field.IsIgnored && !field.IsPrimaryKey {
+			columnCount++
+		}
+	}
+	// 每行大致贡献 columnCount*2（CASE里的id、value各一个占位符）+ 1（IN子句里的id）个绑定参数
+	chunkSize := resolveChunkSize(scope, indirectScopeValue.Len(), columnCount*2+1)
+
+	var totalRowsAffected int64
+	for start := 0; start < indirectScopeValue.Len(); start += chunkSize {
+		end := start + chunkSize
+		if end > indirectScopeValue.Len() {
+			end = indirectScopeValue.Len()
+		}
+
+		chunkScope := scope.db.NewScope(indirectScopeValue.Slice(start, end).Interface())
+		rowsAffected, err := execBatchUpdate(chunkScope, primaryField)
+		if scope.Err(err) != nil {
+			return
+		}
+		totalRowsAffected += rowsAffected
+	}
+
+	scope.db.RowsAffected = totalRowsAffected
+}
+
+// execBatchUpdate runs a single CASE-based UPDATE for every element held by scope
+func execBatchUpdate(scope *Scope, primaryField *Field) (int64, error) {
+	indirectScopeValue := scope.IndirectValue()
+
+	var (
+		columnOrder []string
+		columnCases = map[string][]string{}
+		ids         []string
+	)
+
+	for elementIndex := 0; elementIndex < indirectScopeValue.Len(); elementIndex++ {
+		fields := FiledsWithIndexForBatch(scope, elementIndex)
+
+		var idPlaceholder string
+		for _, field := range fields {
+			if field.IsPrimaryKey {
+				idPlaceholder = scope.AddToVars(field.Field.Interface())
+			}
+		}
+		ids = append(ids, idPlaceholder)
+
+		for _, field := range fields {
+			if field.IsPrimaryKey || !field.IsNormal || field.IsIgnored {
+				continue
+			}
+
+			if _, ok := columnCases[field.DBName]; !ok {
+				columnOrder = append(columnOrder, field.DBName)
+			}
+
+			valuePlaceholder := scope.AddToVars(field.Field.Interface())
+			columnCases[field.DBName] = append(columnCases[field.DBName], fmt.Sprintf("WHEN %v THEN %v", idPlaceholder, valuePlaceholder))
+		}
+	}
+
+	if len(columnOrder) == 0 {
+		return 0, fmt.Errorf("batchUpdateCallback cannot be called for empty columns, %+v given", indirectScopeValue.Interface())
+	}
+
+	quotedPK := scope.Quote(primaryField.DBName)
+	var assignments []string
+	for _, dbName := range columnOrder {
+		quoted := scope.Quote(dbName)
+		assignments = append(assignments, fmt.Sprintf("%v = CASE %v %v END", quoted, quotedPK, strings.Join(columnCases[dbName], " ")))
+	}
+
+	scope.Raw(fmt.Sprintf(
+		"UPDATE %v SET %v WHERE %v IN (%v)",
+		scope.QuotedTableName(),
+		strings.Join(assignments, ", "),
+		quotedPK,
+		strings.Join(ids, ","),
+	))
+
+	result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
+
+// execBatchUpdateViaTempTable is the "bulk" BatchUpdateStrategy: load the incoming rows
+// into a temporary table keyed by primary key, then pull each column back onto the
+// real table with one correlated UPDATE
+func execBatchUpdateViaTempTable(scope *Scope, primaryField *Field) (int64, error) {
+	tempTableName := fmt.Sprintf("%v_batch_update_tmp", scope.TableName())
+
+	var columnOrder []string
+	var columnDefs []string
+	for _, field := range FiledsWithIndexForBatch(scope, 0) {
+		if !field.IsNormal || field.IsIgnored {
+			continue
+		}
+		if field.DBName != primaryField.DBName {
+			columnOrder = append(columnOrder, field.DBName)
+		}
+		columnDefs = append(columnDefs, fmt.Sprintf("%v %v", scope.Quote(field.DBName), scope.Dialect().DataTypeOf(field.StructField)))
+	}
+
+	if len(columnOrder) == 0 {
+		return 0, fmt.Errorf("batchUpdateCallback cannot be called for empty columns, %+v given", scope.IndirectValue().Interface())
+	}
+
+	if _, err := scope.SQLDB().Exec(fmt.Sprintf("CREATE TEMPORARY TABLE %v (%v)", scope.Quote(tempTableName), strings.Join(columnDefs, ", "))); err != nil {
+		return 0, err
+	}
+	defer scope.SQLDB().Exec(fmt.Sprintf("DROP TABLE %v", scope.Quote(tempTableName)))
+
+	tempScope := scope.db.NewScope(scope.IndirectValue().Interface())
+	tempScope.Search.TableName = tempTableName
+	tempScope.callCallbacks(scope.db.parent.callbacks.batchCreates)
+	if tempScope.HasError() {
+		return 0, tempScope.Error
+	}
+
+	quotedTable := scope.QuotedTableName()
+	quotedTemp := scope.Quote(tempTableName)
+	quotedPK := scope.Quote(primaryField.DBName)
+
+	var assignments []string
+	for _, dbName := range columnOrder {
+		quoted := scope.Quote(dbName)
+		assignments = append(assignments, fmt.Sprintf(
+			"%v = (SELECT %v FROM %v WHERE %v.%v = %v.%v)",
+			quoted, quoted, quotedTemp, quotedTemp, quotedPK, quotedTable, quotedPK,
+		))
+	}
+
+	scope.Raw(fmt.Sprintf(
+		"UPDATE %v SET %v WHERE %v IN (SELECT %v FROM %v)",
+		quotedTable,
+		strings.Join(assignments, ", "),
+		quotedPK,
+		quotedPK,
+		quotedTemp,
+	))
+
+	result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
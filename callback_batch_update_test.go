@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"os"
+	"testing"
+)
+
+type batchUpdateUser struct {
+	Id   int64
+	Name string
+	Age  int
+}
+
+func TestBatchUpdateAppliesPerRowValues(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*batchUpdateUser{{Name: "u1", Age: 1}, {Name: "u2", Age: 2}, {Name: "u3", Age: 3}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("seed BatchCreate failed: %v", err)
+	}
+
+	for i, user := range users {
+		user.Age = (i + 1) * 10
+	}
+
+	if err := DB.BatchUpdate(&users).Error; err != nil {
+		t.Fatalf("BatchUpdate failed: %v", err)
+	}
+
+	for i, user := range users {
+		var reloaded batchUpdateUser
+		DB.First(&reloaded, user.Id)
+		if reloaded.Age != (i+1)*10 {
+			t.Errorf("expected age %v for row %v, got %v", (i+1)*10, user.Id, reloaded.Age)
+		}
+	}
+}
+
+// TestExecBatchUpdateViaTempTableAppliesValues exercises the "bulk" BatchUpdateStrategy
+// directly, since none of the built-in dialects (mysql/postgres/sqlite3) select it on
+// their own - dialectBatchUpdateStrategy only returns "bulk" for a Dialect that
+// explicitly opts in, which this test bypasses to prove the temp-table path itself works
+func TestExecBatchUpdateViaTempTableAppliesValues(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*batchUpdateUser{{Name: "bulk-1", Age: 1}, {Name: "bulk-2", Age: 2}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("seed BatchCreate failed: %v", err)
+	}
+
+	for i, user := range users {
+		user.Age = (i + 1) * 100
+	}
+
+	scope := DB.NewScope(&users)
+	rowsAffected, err := execBatchUpdateViaTempTable(scope, scope.PrimaryField())
+	if err != nil {
+		t.Fatalf("execBatchUpdateViaTempTable failed: %v", err)
+	}
+	if rowsAffected != int64(len(users)) {
+		t.Errorf("expected %v rows affected, got %v", len(users), rowsAffected)
+	}
+
+	for i, user := range users {
+		var reloaded batchUpdateUser
+		DB.First(&reloaded, user.Id)
+		if reloaded.Age != (i+1)*100 {
+			t.Errorf("expected age %v for row %v, got %v", (i+1)*100, user.Id, reloaded.Age)
+		}
+	}
+}
+
+func TestBatchDeleteRemovesAllRows(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") == "" {
+		t.Skip("GORM_DIALECT not set, skip")
+	}
+
+	users := []*batchUpdateUser{{Name: "d1"}, {Name: "d2"}}
+	if err := DB.BatchCreate(&users).Error; err != nil {
+		t.Fatalf("seed BatchCreate failed: %v", err)
+	}
+
+	if err := DB.BatchDelete(&users).Error; err != nil {
+		t.Fatalf("BatchDelete failed: %v", err)
+	}
+
+	var count int
+	ids := []int64{users[0].Id, users[1].Id}
+	DB.Model(&batchUpdateUser{}).Where("id IN (?)", ids).Count(&count)
+	if count != 0 {
+		t.Errorf("expected both rows to be deleted, found %v", count)
+	}
+}
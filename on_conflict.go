@@ -0,0 +1,100 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OnConflict configures upsert behavior for BatchCreate, set via
+// db.Set("gorm:on_conflict", &OnConflict{...})
+type OnConflict struct {
+	// Columns is the conflict target; required for postgres/sqlite3, ignored by mysql
+	Columns []string
+	// DoUpdates are the columns to overwrite on conflict; ignored when DoNothing is true
+	DoUpdates []string
+	// DoNothing turns a conflicting insert into a no-op
+	DoNothing bool
+}
+
+// getOnConflict reads the gorm:on_conflict option off scope, if one was set
+func getOnConflict(scope *Scope) *OnConflict {
+	v, ok := scope.Get("gorm:on_conflict")
+	if !ok {
+		return nil
+	}
+	conflict, _ := v.(*OnConflict)
+	return conflict
+}
+
+// onConflictClauseDialect is the optional Dialect extension point for upsert SQL;
+// dialects that don't implement it fall back to buildOnConflictClause's built-in
+// mysql/postgres/sqlite3 support below
+type onConflictClauseDialect interface {
+	OnConflictClause(conflict *OnConflict, columns []string) (string, error)
+}
+
+// buildOnConflictClause renders the dialect-specific upsert tail appended after a
+// batch INSERT's VALUES list; columns are the INSERT's column names, already quoted
+func buildOnConflictClause(scope *Scope, conflict *OnConflict, columns []string) (string, error) {
+	if d, ok := scope.Dialect().(onConflictClauseDialect); ok {
+		return d.OnConflictClause(conflict, columns)
+	}
+
+	switch scope.Dialect().GetName() {
+	case "mysql":
+		if conflict.DoNothing {
+			// MySQL没有真正的DO NOTHING，用一个肯定成立的自我赋值达到同样的忽略效果
+			if len(columns) == 0 {
+				return "", fmt.Errorf("gorm: on_conflict DoNothing needs at least one column on mysql")
+			}
+			first := columns[0]
+			return fmt.Sprintf("ON DUPLICATE KEY UPDATE %v=%v", first, first), nil
+		}
+
+		assignments := make([]string, len(conflict.DoUpdates))
+		for i, column := range conflict.DoUpdates {
+			quoted := scope.Quote(column)
+			assignments[i] = fmt.Sprintf("%v=VALUES(%v)", quoted, quoted)
+		}
+		return fmt.Sprintf("ON DUPLICATE KEY UPDATE %v", strings.Join(assignments, ", ")), nil
+
+	case "postgres", "sqlite3":
+		if len(conflict.Columns) == 0 {
+			return "", fmt.Errorf("gorm: on_conflict needs Columns set on %v", scope.Dialect().GetName())
+		}
+
+		conflictColumns := make([]string, len(conflict.Columns))
+		for i, column := range conflict.Columns {
+			conflictColumns[i] = scope.Quote(column)
+		}
+
+		if conflict.DoNothing {
+			return fmt.Sprintf("ON CONFLICT (%v) DO NOTHING", strings.Join(conflictColumns, ", ")), nil
+		}
+
+		assignments := make([]string, len(conflict.DoUpdates))
+		for i, column := range conflict.DoUpdates {
+			quoted := scope.Quote(column)
+			assignments[i] = fmt.Sprintf("%v=EXCLUDED.%v", quoted, quoted)
+		}
+		return fmt.Sprintf("ON CONFLICT (%v) DO UPDATE SET %v", strings.Join(conflictColumns, ", "), strings.Join(assignments, ", ")), nil
+
+	default:
+		return "", fmt.Errorf("gorm: on_conflict is not supported by dialect %v", scope.Dialect().GetName())
+	}
+}
+
+// onConflictTouchesUpdatedAt reports whether on_conflict's UPDATE branch writes UpdatedAt
+func onConflictTouchesUpdatedAt(scope *Scope) bool {
+	conflict := getOnConflict(scope)
+	if conflict == nil || conflict.DoNothing {
+		return false
+	}
+
+	for _, column := range conflict.DoUpdates {
+		if column == "UpdatedAt" || column == "updated_at" {
+			return true
+		}
+	}
+	return false
+}
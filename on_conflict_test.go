@@ -0,0 +1,138 @@
+package gorm
+
+import (
+	"os"
+	"testing"
+)
+
+type onConflictUser struct {
+	Id    int64
+	Email string
+	Name  string
+}
+
+func TestBatchCreateOnConflictUpdate(t *testing.T) {
+	dialect := os.Getenv("GORM_DIALECT")
+	if dialect != "mysql" && dialect != "postgres" {
+		t.Skip("GORM_DIALECT is not mysql/postgres, skip")
+	}
+
+	existing := []*onConflictUser{{Email: "a@example.com", Name: "old-name"}}
+	if err := DB.BatchCreate(&existing).Error; err != nil {
+		t.Fatalf("seed insert failed: %v", err)
+	}
+
+	upserts := []*onConflictUser{
+		{Email: "a@example.com", Name: "new-name"},
+		{Email: "b@example.com", Name: "brand-new"},
+	}
+	conflict := &OnConflict{Columns: []string{"email"}, DoUpdates: []string{"name"}}
+	if err := DB.Set("gorm:on_conflict", conflict).BatchCreate(&upserts).Error; err != nil {
+		t.Fatalf("upsert BatchCreate failed: %v", err)
+	}
+
+	var updated onConflictUser
+	DB.Where("email = ?", "a@example.com").First(&updated)
+	if updated.Name != "new-name" {
+		t.Errorf("expected conflicting row to be updated to new-name, got %v", updated.Name)
+	}
+
+	var inserted onConflictUser
+	DB.Where("email = ?", "b@example.com").First(&inserted)
+	if inserted.Name != "brand-new" {
+		t.Errorf("expected new row to be inserted, got %v", inserted.Name)
+	}
+}
+
+func TestBatchCreateOnConflictDoNothingMultiRowBackfillsCorrectIDs(t *testing.T) {
+	dialect := os.Getenv("GORM_DIALECT")
+	if dialect != "postgres" {
+		t.Skip("GORM_DIALECT is not postgres, skip")
+	}
+
+	existing := []*onConflictUser{{Email: "mid@example.com", Name: "already-there"}}
+	if err := DB.BatchCreate(&existing).Error; err != nil {
+		t.Fatalf("seed insert failed: %v", err)
+	}
+
+	// existing.Email在中间位置冲突会被跳过，RETURNING因此比输入行数少一行；
+	// 要求每个新插入的元素都拿到自己那一行真正的ID，而不是因为少了一行被错位回写
+	upserts := []*onConflictUser{
+		{Email: "first@example.com", Name: "new-1"},
+		{Email: "mid@example.com", Name: "should-not-apply"},
+		{Email: "third@example.com", Name: "new-3"},
+	}
+	conflict := &OnConflict{Columns: []string{"email"}, DoNothing: true}
+	result := DB.Set("gorm:on_conflict", conflict).BatchCreate(&upserts)
+	if err := result.Error; err != nil {
+		t.Fatalf("upsert BatchCreate failed: %v", err)
+	}
+
+	if result.RowsAffected != 2 {
+		t.Errorf("expected RowsAffected to count only the 2 actually-inserted rows, got %v", result.RowsAffected)
+	}
+
+	var first, third onConflictUser
+	DB.Where("email = ?", "first@example.com").First(&first)
+	DB.Where("email = ?", "third@example.com").First(&third)
+
+	if upserts[0].Id != first.Id {
+		t.Errorf("expected upserts[0].Id (%v) to match the row it actually created (%v)", upserts[0].Id, first.Id)
+	}
+	if upserts[2].Id != third.Id {
+		t.Errorf("expected upserts[2].Id (%v) to match the row it actually created (%v)", upserts[2].Id, third.Id)
+	}
+	if upserts[0].Id == upserts[2].Id {
+		t.Errorf("expected the two newly inserted rows to get distinct IDs, both got %v", upserts[0].Id)
+	}
+}
+
+func TestBatchCreateOnConflictMySQLSkipsStrideBackfill(t *testing.T) {
+	if os.Getenv("GORM_DIALECT") != "mysql" {
+		t.Skip("GORM_DIALECT is not mysql, skip")
+	}
+
+	existing := []*onConflictUser{{Email: "stride@example.com", Name: "already-there"}}
+	if err := DB.BatchCreate(&existing).Error; err != nil {
+		t.Fatalf("seed insert failed: %v", err)
+	}
+
+	// 第二行会走ON DUPLICATE KEY UPDATE分支，不会分配新的自增ID；
+	// LastInsertId()+步长的线性回填假设在这里不成立，所以这一批根本不应该尝试回填
+	upserts := []*onConflictUser{
+		{Email: "new@example.com", Name: "new-1"},
+		{Email: "stride@example.com", Name: "updated"},
+	}
+	conflict := &OnConflict{Columns: []string{"email"}, DoUpdates: []string{"name"}}
+	if err := DB.Set("gorm:on_conflict", conflict).BatchCreate(&upserts).Error; err != nil {
+		t.Fatalf("upsert BatchCreate failed: %v", err)
+	}
+
+	if upserts[1].Id != 0 {
+		t.Errorf("expected the conflicting row's blank Id to stay unset rather than guess a bogus value, got %v", upserts[1].Id)
+	}
+}
+
+func TestBatchCreateOnConflictDoNothing(t *testing.T) {
+	dialect := os.Getenv("GORM_DIALECT")
+	if dialect != "mysql" && dialect != "postgres" {
+		t.Skip("GORM_DIALECT is not mysql/postgres, skip")
+	}
+
+	existing := []*onConflictUser{{Email: "c@example.com", Name: "keep-me"}}
+	if err := DB.BatchCreate(&existing).Error; err != nil {
+		t.Fatalf("seed insert failed: %v", err)
+	}
+
+	upserts := []*onConflictUser{{Email: "c@example.com", Name: "should-not-apply"}}
+	conflict := &OnConflict{Columns: []string{"email"}, DoNothing: true}
+	if err := DB.Set("gorm:on_conflict", conflict).BatchCreate(&upserts).Error; err != nil {
+		t.Fatalf("upsert BatchCreate failed: %v", err)
+	}
+
+	var row onConflictUser
+	DB.Where("email = ?", "c@example.com").First(&row)
+	if row.Name != "keep-me" {
+		t.Errorf("expected DoNothing to leave existing row untouched, got %v", row.Name)
+	}
+}